@@ -0,0 +1,114 @@
+// Package api holds the internal API types for the OpenShift SDN: the
+// cluster-wide ClusterNetwork configuration object and the per-node
+// HostSubnet allocation object.
+package api
+
+import (
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const GroupName = "network.openshift.io"
+
+// Name of the singleton ClusterNetwork object.
+const ClusterNetworkDefault = "default"
+
+const (
+	SingleTenantPluginName  = "redhat/openshift-ovs-subnet"
+	MultiTenantPluginName   = "redhat/openshift-ovs-multitenant"
+	NetworkPolicyPluginName = "redhat/openshift-ovs-networkpolicy"
+)
+
+func IsOpenShiftNetworkPlugin(pluginName string) bool {
+	switch pluginName {
+	case SingleTenantPluginName, MultiTenantPluginName, NetworkPolicyPluginName:
+		return true
+	default:
+		return false
+	}
+}
+
+type ClusterNetwork struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	// Network and HostSubnetLength are kept in sync with the first entry of
+	// ClusterNetworks for nodes that don't understand the multi-CIDR list.
+	Network          string
+	HostSubnetLength uint32
+	ServiceNetwork   string
+	PluginName       string
+
+	// ClusterNetworks is the list of CIDRs from which node subnets are
+	// allocated. New entries may be appended to grow the pod network
+	// without restarting the master; existing entries must not be changed
+	// or removed, since that would orphan already-allocated HostSubnets.
+	ClusterNetworks []ClusterNetworkEntry
+}
+
+type ClusterNetworkEntry struct {
+	CIDR             string
+	HostSubnetLength uint32
+}
+
+func (cn *ClusterNetwork) DeepCopy() *ClusterNetwork {
+	out := *cn
+	if cn.ClusterNetworks != nil {
+		out.ClusterNetworks = make([]ClusterNetworkEntry, len(cn.ClusterNetworks))
+		copy(out.ClusterNetworks, cn.ClusterNetworks)
+	}
+	return &out
+}
+
+type ClusterNetworkList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	Items []ClusterNetwork
+}
+
+type HostSubnet struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Host   string
+	HostIP string
+	Subnet string
+
+	Status HostSubnetStatus
+}
+
+type HostSubnetList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	Items []HostSubnet
+}
+
+// Nodes PATCH their own HostSubnet's status with these conditions from
+// their local OVS health checker; the master aggregates them into events
+// and metrics.
+type HostSubnetStatus struct {
+	Conditions []HostSubnetCondition
+}
+
+type HostSubnetConditionType string
+
+const (
+	// OVSReady is true when the node's local OVS instance is running and
+	// the SDN's flows are programmed.
+	OVSReady HostSubnetConditionType = "OVSReady"
+	// VXLANTunnelsHealthy is true when the node can reach the other nodes'
+	// VXLAN tunnel endpoints.
+	VXLANTunnelsHealthy HostSubnetConditionType = "VXLANTunnelsHealthy"
+)
+
+type HostSubnetCondition struct {
+	Type               HostSubnetConditionType
+	Status             kapi.ConditionStatus
+	LastHeartbeatTime  metav1.Time
+	LastTransitionTime metav1.Time
+	Reason             string
+	Message            string
+}
@@ -0,0 +1,86 @@
+// Package common holds the network-configuration types and helpers shared by
+// the SDN master and node, so that master-only binaries don't have to pull in
+// node/proxy/CNI dependencies (OVS, CNI, etc.) just to parse a ClusterNetwork.
+package common
+
+import (
+	"fmt"
+	"net"
+
+	osapi "github.com/openshift/origin/pkg/sdn/api"
+)
+
+// TUN is excluded when checking the cluster/service networks against the
+// host's local networks, since it carries addresses drawn from those same
+// networks.
+const TUN = "tun0"
+
+type NetworkInfo struct {
+	ClusterNetworks []*net.IPNet
+	ServiceNetwork  *net.IPNet
+}
+
+func ParseNetworkInfo(clusterNetworks []osapi.ClusterNetworkEntry, serviceNetworkCIDR string) (*NetworkInfo, error) {
+	if len(clusterNetworks) == 0 {
+		return nil, fmt.Errorf("no cluster network entries specified")
+	}
+
+	cns := make([]*net.IPNet, 0, len(clusterNetworks))
+	for _, entry := range clusterNetworks {
+		_, cn, err := net.ParseCIDR(entry.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ClusterNetwork CIDR %s: %v", entry.CIDR, err)
+		}
+		cns = append(cns, cn)
+	}
+
+	_, sn, err := net.ParseCIDR(serviceNetworkCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ServiceNetwork CIDR %s: %v", serviceNetworkCIDR, err)
+	}
+
+	for i, first := range cns {
+		if first.Contains(sn.IP) || sn.Contains(first.IP) {
+			return nil, fmt.Errorf("cluster network %s overlaps with service network %s", first.String(), sn.String())
+		}
+		for _, second := range cns[i+1:] {
+			if first.Contains(second.IP) || second.Contains(first.IP) {
+				return nil, fmt.Errorf("cluster network entries %s and %s overlap", first.String(), second.String())
+			}
+		}
+	}
+
+	return &NetworkInfo{ClusterNetworks: cns, ServiceNetwork: sn}, nil
+}
+
+func (ni *NetworkInfo) ClusterNetworkContainsIP(ip net.IP) bool {
+	for _, cn := range ni.ClusterNetworks {
+		if cn.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ni *NetworkInfo) CheckHostNetworks(hostIPNets []*net.IPNet) error {
+	for _, hostIPNet := range hostIPNets {
+		for _, cn := range ni.ClusterNetworks {
+			if cn.Contains(hostIPNet.IP) || hostIPNet.Contains(cn.IP) {
+				return fmt.Errorf("cluster network %s conflicts with host network %s", cn.String(), hostIPNet.String())
+			}
+		}
+		if ni.ServiceNetwork.Contains(hostIPNet.IP) || hostIPNet.Contains(ni.ServiceNetwork.IP) {
+			return fmt.Errorf("service network %s conflicts with host network %s", ni.ServiceNetwork.String(), hostIPNet.String())
+		}
+	}
+	return nil
+}
+
+func ClusterNetworkToString(cn *osapi.ClusterNetwork) string {
+	s := fmt.Sprintf("%s (Network: %s HostSubnetLength: %d ServiceNetwork: %s PluginName: %s)",
+		cn.Name, cn.Network, cn.HostSubnetLength, cn.ServiceNetwork, cn.PluginName)
+	if len(cn.ClusterNetworks) > 1 {
+		s += fmt.Sprintf(" [%d entries]", len(cn.ClusterNetworks))
+	}
+	return s
+}
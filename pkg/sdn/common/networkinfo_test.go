@@ -0,0 +1,103 @@
+package common
+
+import (
+	"net"
+	"testing"
+
+	osapi "github.com/openshift/origin/pkg/sdn/api"
+)
+
+func TestParseNetworkInfo(t *testing.T) {
+	tests := []struct {
+		name            string
+		clusterNetworks []osapi.ClusterNetworkEntry
+		serviceNetwork  string
+		wantErr         bool
+	}{
+		{
+			name:            "single entry, no overlap",
+			clusterNetworks: []osapi.ClusterNetworkEntry{{CIDR: "10.1.0.0/16", HostSubnetLength: 8}},
+			serviceNetwork:  "172.30.0.0/16",
+			wantErr:         false,
+		},
+		{
+			name: "multiple non-overlapping entries",
+			clusterNetworks: []osapi.ClusterNetworkEntry{
+				{CIDR: "10.1.0.0/16", HostSubnetLength: 8},
+				{CIDR: "10.2.0.0/16", HostSubnetLength: 8},
+			},
+			serviceNetwork: "172.30.0.0/16",
+			wantErr:        false,
+		},
+		{
+			name:            "no cluster network entries",
+			clusterNetworks: nil,
+			serviceNetwork:  "172.30.0.0/16",
+			wantErr:         true,
+		},
+		{
+			name:            "unparseable cluster network CIDR",
+			clusterNetworks: []osapi.ClusterNetworkEntry{{CIDR: "not-a-cidr", HostSubnetLength: 8}},
+			serviceNetwork:  "172.30.0.0/16",
+			wantErr:         true,
+		},
+		{
+			name:            "unparseable service network CIDR",
+			clusterNetworks: []osapi.ClusterNetworkEntry{{CIDR: "10.1.0.0/16", HostSubnetLength: 8}},
+			serviceNetwork:  "not-a-cidr",
+			wantErr:         true,
+		},
+		{
+			name: "cluster network entries overlap each other",
+			clusterNetworks: []osapi.ClusterNetworkEntry{
+				{CIDR: "10.1.0.0/16", HostSubnetLength: 8},
+				{CIDR: "10.1.128.0/17", HostSubnetLength: 8},
+			},
+			serviceNetwork: "172.30.0.0/16",
+			wantErr:        true,
+		},
+		{
+			name:            "cluster network overlaps the service network",
+			clusterNetworks: []osapi.ClusterNetworkEntry{{CIDR: "172.30.0.0/15", HostSubnetLength: 8}},
+			serviceNetwork:  "172.30.0.0/16",
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNetworkInfo(tt.clusterNetworks, tt.serviceNetwork)
+			if tt.wantErr && err == nil {
+				t.Errorf("expected an error, got none")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestClusterNetworkContainsIP(t *testing.T) {
+	ni, err := ParseNetworkInfo([]osapi.ClusterNetworkEntry{
+		{CIDR: "10.1.0.0/16", HostSubnetLength: 8},
+		{CIDR: "10.2.0.0/16", HostSubnetLength: 8},
+	}, "172.30.0.0/16")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"10.2.2.3", true},
+		{"10.3.2.3", false},
+		{"172.30.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := ni.ClusterNetworkContainsIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("ClusterNetworkContainsIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package master
+
+import (
+	"time"
+
+	log "github.com/golang/glog"
+
+	sdnapi "github.com/openshift/origin/pkg/sdn/api"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/cache"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Used when networkConfig doesn't configure a grace period explicitly.
+const defaultOVSHealthGracePeriod = 2 * time.Minute
+
+var nodeOVSHealthy = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "openshift_sdn",
+		Name:      "node_ovs_healthy",
+		Help:      "Whether a node's OVS is reporting ready, as observed from the OVSReady condition in its HostSubnet status (1 = healthy, 0 = not).",
+	},
+	[]string{"node"},
+)
+
+func init() {
+	prometheus.MustRegister(nodeOVSHealthy)
+}
+
+// Nodes PATCH their own HostSubnet's status subresource with their local OVS
+// health conditions; see NodeHostSubnetStatusRule for the RBAC rule that
+// permits it.
+func (master *OsdnMaster) watchHostSubnetHealth() {
+	informer := master.informers.HostSubnets().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			master.checkHostSubnetHealth(obj.(*sdnapi.HostSubnet))
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			master.checkHostSubnetHealth(newObj.(*sdnapi.HostSubnet))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if hs, ok := obj.(*sdnapi.HostSubnet); ok {
+				nodeOVSHealthy.DeleteLabelValues(hs.Host)
+				master.clearHealthEventState(hs.Host, sdnapi.OVSReady)
+				master.clearHealthEventState(hs.Host, sdnapi.VXLANTunnelsHealthy)
+			}
+		},
+	})
+	go informer.Run(wait.NeverStop)
+}
+
+func (master *OsdnMaster) checkHostSubnetHealth(hs *sdnapi.HostSubnet) {
+	if ovsReady := findHostSubnetCondition(hs.Status.Conditions, sdnapi.OVSReady); ovsReady != nil {
+		if ovsReady.Status == kapi.ConditionTrue {
+			nodeOVSHealthy.WithLabelValues(hs.Host).Set(1)
+			master.clearHealthEventState(hs.Host, sdnapi.OVSReady)
+		} else {
+			nodeOVSHealthy.WithLabelValues(hs.Host).Set(0)
+			master.warnOnceForTransition(hs, sdnapi.OVSReady, ovsReady, "NodeOVSUnhealthy")
+		}
+	}
+
+	if tunnels := findHostSubnetCondition(hs.Status.Conditions, sdnapi.VXLANTunnelsHealthy); tunnels != nil {
+		if tunnels.Status == kapi.ConditionTrue {
+			master.clearHealthEventState(hs.Host, sdnapi.VXLANTunnelsHealthy)
+		} else {
+			master.warnOnceForTransition(hs, sdnapi.VXLANTunnelsHealthy, tunnels, "NodeVXLANTunnelsUnhealthy")
+		}
+	}
+
+	log.V(5).Infof("Checked OVS health conditions for node %s", hs.Host)
+}
+
+// Warns at most once per LastTransitionTime -- otherwise, since nodes keep
+// bumping LastHeartbeatTime while unhealthy, every heartbeat update would
+// re-fire the same event.
+func (master *OsdnMaster) warnOnceForTransition(hs *sdnapi.HostSubnet, condType sdnapi.HostSubnetConditionType, cond *sdnapi.HostSubnetCondition, reason string) {
+	if time.Since(cond.LastTransitionTime.Time) < master.ovsHealthGracePeriod {
+		return
+	}
+
+	key := healthEventKey(hs.Host, condType)
+
+	master.healthEventMu.Lock()
+	defer master.healthEventMu.Unlock()
+	if warned, ok := master.healthEventState[key]; ok && warned.Equal(cond.LastTransitionTime) {
+		return
+	}
+	master.healthEventState[key] = cond.LastTransitionTime
+
+	master.recorder.Eventf(hs, kapi.EventTypeWarning, reason,
+		"Node %s has reported %s=%s for more than %s: %s", hs.Host, condType, cond.Status, master.ovsHealthGracePeriod, cond.Message)
+}
+
+func (master *OsdnMaster) clearHealthEventState(node string, condType sdnapi.HostSubnetConditionType) {
+	key := healthEventKey(node, condType)
+
+	master.healthEventMu.Lock()
+	defer master.healthEventMu.Unlock()
+	delete(master.healthEventState, key)
+}
+
+func healthEventKey(node string, condType sdnapi.HostSubnetConditionType) string {
+	return node + "/" + string(condType)
+}
+
+func findHostSubnetCondition(conditions []sdnapi.HostSubnetCondition, t sdnapi.HostSubnetConditionType) *sdnapi.HostSubnetCondition {
+	for i := range conditions {
+		if conditions[i].Type == t {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package master
+
+import (
+	"testing"
+	"time"
+
+	sdnapi "github.com/openshift/origin/pkg/sdn/api"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/record"
+)
+
+func newTestMaster() *OsdnMaster {
+	return &OsdnMaster{
+		recorder:             record.NewFakeRecorder(10),
+		ovsHealthGracePeriod: time.Minute,
+		healthEventState:     map[string]metav1.Time{},
+	}
+}
+
+func unhealthyHostSubnet(node string, transitionTime time.Time) *sdnapi.HostSubnet {
+	return &sdnapi.HostSubnet{
+		Host: node,
+		Status: sdnapi.HostSubnetStatus{
+			Conditions: []sdnapi.HostSubnetCondition{
+				{
+					Type:               sdnapi.OVSReady,
+					Status:             kapi.ConditionFalse,
+					LastTransitionTime: metav1.NewTime(transitionTime),
+					LastHeartbeatTime:  metav1.Now(),
+				},
+			},
+		},
+	}
+}
+
+func TestWarnOnceForTransition(t *testing.T) {
+	master := newTestMaster()
+	staleTransition := time.Now().Add(-2 * time.Minute)
+
+	hs := unhealthyHostSubnet("node1", staleTransition)
+	cond := &hs.Status.Conditions[0]
+
+	master.warnOnceForTransition(hs, sdnapi.OVSReady, cond, "NodeOVSUnhealthy")
+	if _, ok := master.healthEventState[healthEventKey("node1", sdnapi.OVSReady)]; !ok {
+		t.Fatalf("expected a warned transition to be recorded")
+	}
+
+	// A second heartbeat for the same transition must not re-warn; this
+	// asserts that the recorded state key is untouched rather than reading
+	// the fake recorder's event channel.
+	before := master.healthEventState[healthEventKey("node1", sdnapi.OVSReady)]
+	hs.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+	master.warnOnceForTransition(hs, sdnapi.OVSReady, &hs.Status.Conditions[0], "NodeOVSUnhealthy")
+	after := master.healthEventState[healthEventKey("node1", sdnapi.OVSReady)]
+	if !before.Equal(after) {
+		t.Errorf("expected the recorded transition to be unchanged across repeated heartbeats")
+	}
+
+	// A genuinely new transition (node flapped back healthy and unhealthy
+	// again) must be allowed to warn again.
+	newTransition := time.Now().Add(-90 * time.Second)
+	hs.Status.Conditions[0].LastTransitionTime = metav1.NewTime(newTransition)
+	master.warnOnceForTransition(hs, sdnapi.OVSReady, &hs.Status.Conditions[0], "NodeOVSUnhealthy")
+	after = master.healthEventState[healthEventKey("node1", sdnapi.OVSReady)]
+	if !after.Time.Equal(newTransition) {
+		t.Errorf("expected the new transition time to be recorded, got %v", after)
+	}
+}
+
+func TestWarnOnceForTransitionRespectsGracePeriod(t *testing.T) {
+	master := newTestMaster()
+	hs := unhealthyHostSubnet("node1", time.Now())
+
+	master.warnOnceForTransition(hs, sdnapi.OVSReady, &hs.Status.Conditions[0], "NodeOVSUnhealthy")
+	if _, ok := master.healthEventState[healthEventKey("node1", sdnapi.OVSReady)]; ok {
+		t.Errorf("expected no warning before the grace period elapses")
+	}
+}
+
+func TestClearHealthEventState(t *testing.T) {
+	master := newTestMaster()
+	key := healthEventKey("node1", sdnapi.OVSReady)
+	master.healthEventState[key] = metav1.Now()
+
+	master.clearHealthEventState("node1", sdnapi.OVSReady)
+	if _, ok := master.healthEventState[key]; ok {
+		t.Errorf("expected state to be cleared")
+	}
+}
@@ -0,0 +1,292 @@
+// Package master contains the master side of the SDN plugin: reconciling the
+// ClusterNetwork object, allocating per-node host subnets, and (for the
+// multitenant/network-policy plugins) assigning VNIDs. It imports sdn/common
+// but not sdn/node, so a master-only binary can import it without dragging
+// in the node's OVS/CNI dependencies.
+package master
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+
+	osclient "github.com/openshift/origin/pkg/client"
+	osconfigapi "github.com/openshift/origin/pkg/cmd/server/api"
+	"github.com/openshift/origin/pkg/controller/shared"
+	sdnapi "github.com/openshift/origin/pkg/sdn/api"
+	"github.com/openshift/origin/pkg/sdn/common"
+	"github.com/openshift/origin/pkg/util/netutils"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/record"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	kclientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
+	kcoretyped "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/core/internalversion"
+)
+
+const clusterNetworkEventComponent = "openshift-sdn-master"
+
+type OsdnMaster struct {
+	kClient   kclientset.Interface
+	osClient  *osclient.Client
+	vnids     *masterVNIDMap
+	informers shared.InformerFactory
+	recorder  record.EventRecorder
+
+	// Guards networkInfo and subnetAllocator: set at Start() time, then
+	// mutated again by the ClusterNetwork informer's goroutine whenever it
+	// reconciles a live config change (see reconcile.go)
+	networkMu       sync.Mutex
+	networkInfo     *common.NetworkInfo
+	subnetAllocator *subnetAllocator
+
+	// Holds Node IP used in creating host subnet for a node
+	hostSubnetNodeIPs map[ktypes.UID]string
+
+	// Guards lastGoodClusterNetwork and pendingRevertRV, read/written from
+	// the ClusterNetwork informer's goroutine (see reconcile.go)
+	clusterNetworkMu sync.Mutex
+	// Master's own last-applied ClusterNetwork state; updates are diffed
+	// against this, not against the informer's previous delivery
+	lastGoodClusterNetwork *sdnapi.ClusterNetwork
+	// ResourceVersion of a corrective Update the master just issued, cleared
+	// (and the update ignored) the first time the informer echoes it back
+	pendingRevertRV string
+
+	ovsHealthGracePeriod time.Duration
+	// Guards healthEventState
+	healthEventMu sync.Mutex
+	// Maps "<node>/<conditionType>" to the LastTransitionTime last warned
+	// about, so watchHostSubnetHealth fires once per transition
+	healthEventState map[string]metav1.Time
+}
+
+func newEventRecorder(kClient kclientset.Interface) record.EventRecorder {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(log.Infof)
+	eventBroadcaster.StartRecordingToSink(&kcoretyped.EventSinkImpl{Interface: kClient.Core().Events("")})
+	return eventBroadcaster.NewRecorder(kapi.Scheme, kapi.EventSource{Component: clusterNetworkEventComponent})
+}
+
+func clusterNetworkEntries(networkConfig osconfigapi.MasterNetworkConfig) []sdnapi.ClusterNetworkEntry {
+	// Fall back to the legacy single-CIDR fields if the operator hasn't set
+	// the new list.
+	if len(networkConfig.ClusterNetworks) > 0 {
+		return networkConfig.ClusterNetworks
+	}
+	return []sdnapi.ClusterNetworkEntry{
+		{CIDR: networkConfig.ClusterNetworkCIDR, HostSubnetLength: networkConfig.HostSubnetLength},
+	}
+}
+
+func clusterNetworkEntriesFromClusterNetwork(cn *sdnapi.ClusterNetwork) []sdnapi.ClusterNetworkEntry {
+	// Synthesize a single entry from the legacy fields if cn predates the
+	// multi-CIDR list.
+	if len(cn.ClusterNetworks) > 0 {
+		return cn.ClusterNetworks
+	}
+	if cn.Network == "" {
+		return nil
+	}
+	return []sdnapi.ClusterNetworkEntry{{CIDR: cn.Network, HostSubnetLength: cn.HostSubnetLength}}
+}
+
+func clusterNetworkEntriesEqual(a, b []sdnapi.ClusterNetworkEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].CIDR != b[i].CIDR || a[i].HostSubnetLength != b[i].HostSubnetLength {
+			return false
+		}
+	}
+	return true
+}
+
+func ovsHealthGracePeriod(networkConfig osconfigapi.MasterNetworkConfig) time.Duration {
+	if networkConfig.OVSHealthCheckGracePeriod > 0 {
+		return networkConfig.OVSHealthCheckGracePeriod
+	}
+	return defaultOVSHealthGracePeriod
+}
+
+// existing is a floor, not the full set to converge on: a live reconcile
+// (see reconcile.go) may have appended entries that the static networkConfig
+// doesn't list yet, and restarting must not drop or reorder them.
+func mergeClusterNetworkEntries(existing, want []sdnapi.ClusterNetworkEntry) []sdnapi.ClusterNetworkEntry {
+	haveCIDR := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		haveCIDR[e.CIDR] = true
+	}
+	merged := append([]sdnapi.ClusterNetworkEntry{}, existing...)
+	for _, w := range want {
+		if !haveCIDR[w.CIDR] {
+			merged = append(merged, w)
+		}
+	}
+	return merged
+}
+
+func Start(networkConfig osconfigapi.MasterNetworkConfig, osClient *osclient.Client, kClient kclientset.Interface, informers shared.InformerFactory) error {
+	if !sdnapi.IsOpenShiftNetworkPlugin(networkConfig.NetworkPluginName) {
+		return nil
+	}
+
+	log.Infof("Initializing SDN master of type %q", networkConfig.NetworkPluginName)
+
+	master := &OsdnMaster{
+		kClient:              kClient,
+		osClient:             osClient,
+		informers:            informers,
+		recorder:             newEventRecorder(kClient),
+		hostSubnetNodeIPs:    map[ktypes.UID]string{},
+		ovsHealthGracePeriod: ovsHealthGracePeriod(networkConfig),
+		healthEventState:     map[string]metav1.Time{},
+	}
+
+	wantEntries := clusterNetworkEntries(networkConfig)
+
+	createConfig := false
+	mergedEntries := wantEntries
+	cn, err := master.osClient.ClusterNetwork().Get(sdnapi.ClusterNetworkDefault, metav1.GetOptions{})
+	if err == nil {
+		mergedEntries = mergeClusterNetworkEntries(clusterNetworkEntriesFromClusterNetwork(cn), wantEntries)
+	} else {
+		cn = &sdnapi.ClusterNetwork{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterNetwork"},
+			ObjectMeta: metav1.ObjectMeta{Name: sdnapi.ClusterNetworkDefault},
+		}
+		createConfig = true
+	}
+
+	master.networkInfo, err = common.ParseNetworkInfo(mergedEntries, networkConfig.ServiceNetworkCIDR)
+	if err != nil {
+		return err
+	}
+
+	updateConfig := false
+	if !createConfig {
+		if !clusterNetworkEntriesEqual(mergedEntries, clusterNetworkEntriesFromClusterNetwork(cn)) ||
+			master.networkInfo.ServiceNetwork.String() != cn.ServiceNetwork ||
+			networkConfig.NetworkPluginName != cn.PluginName {
+			updateConfig = true
+		}
+	}
+	if createConfig || updateConfig {
+		if err = master.checkClusterNetworkAgainstLocalNetworks(cn); err != nil {
+			return err
+		}
+		if err = master.checkClusterNetworkAgainstClusterObjects(cn, master.networkInfo); err != nil {
+			return err
+		}
+		cn.ClusterNetworks = mergedEntries
+		// Keep the legacy singleton fields populated from the first entry so
+		// that nodes which don't understand ClusterNetworks yet (or a
+		// downgrade back to a single entry) still work.
+		cn.Network = mergedEntries[0].CIDR
+		cn.HostSubnetLength = mergedEntries[0].HostSubnetLength
+		cn.ServiceNetwork = master.networkInfo.ServiceNetwork.String()
+		cn.PluginName = networkConfig.NetworkPluginName
+	}
+
+	if createConfig {
+		created, err := master.osClient.ClusterNetwork().Create(cn)
+		if err != nil {
+			return err
+		}
+		cn = created
+		log.Infof("Created ClusterNetwork %s", common.ClusterNetworkToString(cn))
+		master.recorder.Eventf(cn, kapi.EventTypeNormal, "ClusterNetworkCreated", "Created ClusterNetwork %s", common.ClusterNetworkToString(cn))
+	} else if updateConfig {
+		updated, err := master.osClient.ClusterNetwork().Update(cn)
+		if err != nil {
+			return err
+		}
+		cn = updated
+		log.Infof("Updated ClusterNetwork %s", common.ClusterNetworkToString(cn))
+		master.recorder.Eventf(cn, kapi.EventTypeNormal, "ClusterNetworkUpdated", "Updated ClusterNetwork %s", common.ClusterNetworkToString(cn))
+	}
+	master.lastGoodClusterNetwork = cn.DeepCopy()
+
+	if err = master.SubnetStartMaster(mergedEntries); err != nil {
+		return err
+	}
+
+	switch networkConfig.NetworkPluginName {
+	case sdnapi.MultiTenantPluginName:
+		master.vnids = newMasterVNIDMap(true)
+		if err = master.VnidStartMaster(); err != nil {
+			return err
+		}
+	case sdnapi.NetworkPolicyPluginName:
+		master.vnids = newMasterVNIDMap(false)
+		if err = master.VnidStartMaster(); err != nil {
+			return err
+		}
+	}
+
+	master.watchClusterNetwork()
+	master.watchHostSubnetHealth()
+
+	return nil
+}
+
+func (master *OsdnMaster) checkClusterNetworkAgainstLocalNetworks(cn *sdnapi.ClusterNetwork) error {
+	hostIPNets, _, err := netutils.GetHostIPNetworks([]string{common.TUN})
+	if err != nil {
+		return err
+	}
+	if err := master.networkInfo.CheckHostNetworks(hostIPNets); err != nil {
+		master.recorder.Eventf(cn, kapi.EventTypeWarning, "HostInterfaceOverlap", "%v", err)
+		return err
+	}
+	return nil
+}
+
+func (master *OsdnMaster) checkClusterNetworkAgainstClusterObjects(cn *sdnapi.ClusterNetwork, ni *common.NetworkInfo) error {
+	errList := []error{}
+
+	// Ensure each host subnet is within the cluster network
+	subnets, err := master.osClient.HostSubnets().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error in initializing/fetching subnets: %v", err)
+	}
+	for _, sub := range subnets.Items {
+		subnetIP, _, _ := net.ParseCIDR(sub.Subnet)
+		if subnetIP == nil {
+			err := fmt.Errorf("failed to parse network address: %s", sub.Subnet)
+			errList = append(errList, err)
+			master.recorder.Eventf(&sub, kapi.EventTypeWarning, "InvalidSubnet", "%v", err)
+			continue
+		}
+		if !ni.ClusterNetworkContainsIP(subnetIP) {
+			err := fmt.Errorf("existing node subnet: %s is not part of any configured cluster network", sub.Subnet)
+			errList = append(errList, err)
+			master.recorder.Eventf(&sub, kapi.EventTypeWarning, "SubnetOutOfClusterNetwork", "%v", err)
+			master.recorder.Eventf(cn, kapi.EventTypeWarning, "SubnetOutOfClusterNetwork", "%v", err)
+		}
+	}
+
+	// Ensure each service is within the services network
+	services, err := master.kClient.Core().Services(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, svc := range services.Items {
+		svcIP := net.ParseIP(svc.Spec.ClusterIP)
+		if svcIP != nil && !ni.ServiceNetwork.Contains(svcIP) {
+			err := fmt.Errorf("existing service with IP: %s is not part of service network: %s", svc.Spec.ClusterIP, ni.ServiceNetwork.String())
+			errList = append(errList, err)
+			master.recorder.Eventf(&svc, kapi.EventTypeWarning, "ServiceOutOfServiceNetwork", "%v", err)
+			master.recorder.Eventf(cn, kapi.EventTypeWarning, "ServiceOutOfServiceNetwork", "%v", err)
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
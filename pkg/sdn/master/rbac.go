@@ -0,0 +1,18 @@
+package master
+
+import (
+	sdnapi "github.com/openshift/origin/pkg/sdn/api"
+
+	rbac "k8s.io/kubernetes/pkg/apis/rbac"
+)
+
+// Grants the "system:node" role get/update/patch on the HostSubnet status
+// subresource; must be added to the node bootstrap policy alongside the
+// existing HostSubnet read/write rules. The rule names no resources, so it
+// does not limit a node to its own HostSubnet: it covers every HostSubnet's
+// status.
+var NodeHostSubnetStatusRule = rbac.PolicyRule{
+	APIGroups: []string{sdnapi.GroupName},
+	Resources: []string{"hostsubnets/status"},
+	Verbs:     []string{"get", "update", "patch"},
+}
@@ -0,0 +1,123 @@
+package master
+
+import (
+	"fmt"
+
+	log "github.com/golang/glog"
+
+	sdnapi "github.com/openshift/origin/pkg/sdn/api"
+	"github.com/openshift/origin/pkg/sdn/common"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/cache"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func (master *OsdnMaster) watchClusterNetwork() {
+	informer := master.informers.ClusterNetworks().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: master.handleClusterNetworkUpdate,
+	})
+	go informer.Run(wait.NeverStop)
+}
+
+// Diffs every incoming version against master.lastGoodClusterNetwork rather
+// than the informer's previous delivery (oldObj), and ignores the echo of
+// its own corrective reverts -- otherwise the master's own revert Update
+// looks like a second forbidden change once the informer echoes it back,
+// and it reverts the revert forever.
+func (master *OsdnMaster) handleClusterNetworkUpdate(oldObj, newObj interface{}) {
+	newCN, ok := newObj.(*sdnapi.ClusterNetwork)
+	if !ok || newCN.Name != sdnapi.ClusterNetworkDefault {
+		return
+	}
+
+	master.clusterNetworkMu.Lock()
+	defer master.clusterNetworkMu.Unlock()
+
+	if master.pendingRevertRV != "" && newCN.ResourceVersion == master.pendingRevertRV {
+		// This is the informer echoing back a revert we wrote ourselves;
+		// lastGoodClusterNetwork was already updated when we wrote it.
+		master.pendingRevertRV = ""
+		return
+	}
+
+	lastGood := master.lastGoodClusterNetwork
+	if lastGood == nil || newCN.ResourceVersion == lastGood.ResourceVersion {
+		return
+	}
+
+	goodEntries := clusterNetworkEntriesFromClusterNetwork(lastGood)
+	newEntries := clusterNetworkEntriesFromClusterNetwork(newCN)
+
+	if err := validateClusterNetworkChange(lastGood, newCN, goodEntries, newEntries); err != nil {
+		log.Warningf("Rejecting ClusterNetwork update: %v", err)
+		master.recorder.Eventf(newCN, kapi.EventTypeWarning, "InvalidClusterNetworkUpdate", "%v; reverting to the previous configuration", err)
+		reverted, revertErr := master.revertClusterNetwork(lastGood, newCN)
+		if revertErr != nil {
+			log.Errorf("Failed to revert invalid ClusterNetwork update: %v", revertErr)
+			return
+		}
+		master.pendingRevertRV = reverted.ResourceVersion
+		master.lastGoodClusterNetwork = reverted
+		return
+	}
+
+	if clusterNetworkEntriesEqual(goodEntries, newEntries) && lastGood.ServiceNetwork == newCN.ServiceNetwork {
+		// Nothing that affects network allocation changed (e.g. just labels).
+		master.lastGoodClusterNetwork = newCN.DeepCopy()
+		return
+	}
+
+	ni, err := common.ParseNetworkInfo(newEntries, newCN.ServiceNetwork)
+	if err != nil {
+		log.Errorf("Error parsing updated ClusterNetwork %s: %v", newCN.Name, err)
+		return
+	}
+	if err := master.checkClusterNetworkAgainstClusterObjects(newCN, ni); err != nil {
+		log.Errorf("Updated ClusterNetwork %s failed validation against existing objects: %v", newCN.Name, err)
+		return
+	}
+	if err := master.SubnetStartMaster(newEntries); err != nil {
+		log.Errorf("Error reconfiguring subnet allocator for updated ClusterNetwork %s: %v", newCN.Name, err)
+		return
+	}
+	master.networkMu.Lock()
+	master.networkInfo = ni
+	master.networkMu.Unlock()
+	master.lastGoodClusterNetwork = newCN.DeepCopy()
+
+	log.Infof("Reconfigured SDN master for updated ClusterNetwork %s", common.ClusterNetworkToString(newCN))
+	master.recorder.Eventf(newCN, kapi.EventTypeNormal, "ClusterNetworkReconfigured", "Master reconfigured for updated ClusterNetwork %s", common.ClusterNetworkToString(newCN))
+}
+
+// lastGood must be the master's own last-applied state, not merely the
+// previous informer delivery.
+func validateClusterNetworkChange(lastGood, newCN *sdnapi.ClusterNetwork, goodEntries, newEntries []sdnapi.ClusterNetworkEntry) error {
+	if lastGood.PluginName != newCN.PluginName {
+		return fmt.Errorf("changing the network plugin (%s -> %s) is not supported without a master restart", lastGood.PluginName, newCN.PluginName)
+	}
+	if lastGood.ServiceNetwork != newCN.ServiceNetwork {
+		return fmt.Errorf("changing the service network (%s -> %s) would orphan existing services", lastGood.ServiceNetwork, newCN.ServiceNetwork)
+	}
+	if len(newEntries) < len(goodEntries) {
+		return fmt.Errorf("removing cluster network entries would orphan existing host subnets")
+	}
+	for i, good := range goodEntries {
+		if newEntries[i] != good {
+			return fmt.Errorf("changing existing cluster network entry %s would orphan existing host subnets; entries may only be appended", good.CIDR)
+		}
+	}
+	return nil
+}
+
+func (master *OsdnMaster) revertClusterNetwork(lastGood, bad *sdnapi.ClusterNetwork) (*sdnapi.ClusterNetwork, error) {
+	reverted := bad.DeepCopy()
+	reverted.ClusterNetworks = lastGood.ClusterNetworks
+	reverted.Network = lastGood.Network
+	reverted.HostSubnetLength = lastGood.HostSubnetLength
+	reverted.ServiceNetwork = lastGood.ServiceNetwork
+	reverted.PluginName = lastGood.PluginName
+	return master.osClient.ClusterNetwork().Update(reverted)
+}
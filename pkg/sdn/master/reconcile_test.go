@@ -0,0 +1,102 @@
+package master
+
+import (
+	"testing"
+
+	sdnapi "github.com/openshift/origin/pkg/sdn/api"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateClusterNetworkChange(t *testing.T) {
+	base := []sdnapi.ClusterNetworkEntry{{CIDR: "10.1.0.0/16", HostSubnetLength: 8}}
+
+	tests := []struct {
+		name       string
+		lastGood   *sdnapi.ClusterNetwork
+		newCN      *sdnapi.ClusterNetwork
+		newEntries []sdnapi.ClusterNetworkEntry
+		wantErr    bool
+	}{
+		{
+			name:       "append a new entry is allowed",
+			lastGood:   &sdnapi.ClusterNetwork{PluginName: "p", ServiceNetwork: "172.30.0.0/16"},
+			newCN:      &sdnapi.ClusterNetwork{PluginName: "p", ServiceNetwork: "172.30.0.0/16"},
+			newEntries: append(append([]sdnapi.ClusterNetworkEntry{}, base...), sdnapi.ClusterNetworkEntry{CIDR: "10.2.0.0/16", HostSubnetLength: 8}),
+			wantErr:    false,
+		},
+		{
+			name:       "removing an entry is rejected",
+			lastGood:   &sdnapi.ClusterNetwork{PluginName: "p", ServiceNetwork: "172.30.0.0/16"},
+			newCN:      &sdnapi.ClusterNetwork{PluginName: "p", ServiceNetwork: "172.30.0.0/16"},
+			newEntries: nil,
+			wantErr:    true,
+		},
+		{
+			name:       "changing an existing entry's host subnet length is rejected",
+			lastGood:   &sdnapi.ClusterNetwork{PluginName: "p", ServiceNetwork: "172.30.0.0/16"},
+			newCN:      &sdnapi.ClusterNetwork{PluginName: "p", ServiceNetwork: "172.30.0.0/16"},
+			newEntries: []sdnapi.ClusterNetworkEntry{{CIDR: "10.1.0.0/16", HostSubnetLength: 9}},
+			wantErr:    true,
+		},
+		{
+			name:       "changing the plugin name is rejected",
+			lastGood:   &sdnapi.ClusterNetwork{PluginName: "p", ServiceNetwork: "172.30.0.0/16"},
+			newCN:      &sdnapi.ClusterNetwork{PluginName: "q", ServiceNetwork: "172.30.0.0/16"},
+			newEntries: base,
+			wantErr:    true,
+		},
+		{
+			name:       "changing the service network is rejected",
+			lastGood:   &sdnapi.ClusterNetwork{PluginName: "p", ServiceNetwork: "172.30.0.0/16"},
+			newCN:      &sdnapi.ClusterNetwork{PluginName: "p", ServiceNetwork: "172.31.0.0/16"},
+			newEntries: base,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClusterNetworkChange(tt.lastGood, tt.newCN, base, tt.newEntries)
+			if tt.wantErr && err == nil {
+				t.Errorf("expected an error, got none")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestHandleClusterNetworkUpdateIgnoresSelfRevertEcho guards against the
+// master reverting its own revert forever: once it reverts a bad update, the
+// informer's echo of that corrective Update (and, separately, any later
+// delivery that already matches lastGoodClusterNetwork) must be ignored
+// rather than re-validated against a stale comparison point.
+func TestHandleClusterNetworkUpdateIgnoresSelfRevertEcho(t *testing.T) {
+	good := &sdnapi.ClusterNetwork{
+		ObjectMeta:     metav1.ObjectMeta{Name: sdnapi.ClusterNetworkDefault, ResourceVersion: "1"},
+		PluginName:     "p",
+		ServiceNetwork: "172.30.0.0/16",
+	}
+
+	master := &OsdnMaster{lastGoodClusterNetwork: good, pendingRevertRV: "2"}
+
+	echo := &sdnapi.ClusterNetwork{
+		ObjectMeta:     metav1.ObjectMeta{Name: sdnapi.ClusterNetworkDefault, ResourceVersion: "2"},
+		PluginName:     "p",
+		ServiceNetwork: "172.30.0.0/16",
+	}
+	master.handleClusterNetworkUpdate(good, echo)
+
+	if master.pendingRevertRV != "" {
+		t.Errorf("expected pendingRevertRV to be cleared after the echo, got %q", master.pendingRevertRV)
+	}
+	if master.lastGoodClusterNetwork != good {
+		t.Errorf("expected lastGoodClusterNetwork to be left untouched by the echo")
+	}
+
+	// A later delivery that already matches lastGoodClusterNetwork's
+	// ResourceVersion must also be a no-op (no osClient call, no panic).
+	master.handleClusterNetworkUpdate(echo, good)
+}
@@ -0,0 +1,110 @@
+package master
+
+import (
+	"fmt"
+	"net"
+
+	sdnapi "github.com/openshift/origin/pkg/sdn/api"
+	"github.com/openshift/origin/pkg/util/netutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type subnetAllocator struct {
+	entries []*allocatorEntry
+}
+
+// Subset of netutils.SubnetAllocator that allocatorEntry needs; factored out
+// so the GetNetwork/ReleaseNetwork fallback logic below can be unit tested
+// without a real CIDR allocator.
+type networkAllocator interface {
+	GetNetwork() (*net.IPNet, error)
+	ReleaseNetwork(sn *net.IPNet) error
+}
+
+type allocatorEntry struct {
+	network   *net.IPNet
+	allocator networkAllocator
+}
+
+func newSubnetAllocator(clusterNetworks []sdnapi.ClusterNetworkEntry, existingSubnets []*net.IPNet) (*subnetAllocator, error) {
+	sa := &subnetAllocator{}
+	for _, entry := range clusterNetworks {
+		_, cn, err := net.ParseCIDR(entry.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ClusterNetwork CIDR %s: %v", entry.CIDR, err)
+		}
+		var inRange []*net.IPNet
+		for _, existing := range existingSubnets {
+			if cn.Contains(existing.IP) {
+				inRange = append(inRange, existing)
+			}
+		}
+		allocator, err := netutils.NewSubnetAllocator(entry.CIDR, entry.HostSubnetLength, inRange)
+		if err != nil {
+			return nil, fmt.Errorf("error creating subnet allocator for %s: %v", entry.CIDR, err)
+		}
+		sa.entries = append(sa.entries, &allocatorEntry{network: cn, allocator: allocator})
+	}
+	return sa, nil
+}
+
+// If hint is non-nil, the entry that contains it is tried first (e.g. to
+// keep a renewing node's subnet in the same cluster network entry it was
+// already part of).
+func (sa *subnetAllocator) GetNetwork(hint net.IP) (*net.IPNet, error) {
+	if hint != nil {
+		for _, e := range sa.entries {
+			if e.network.Contains(hint) {
+				if sn, err := e.allocator.GetNetwork(); err == nil {
+					return sn, nil
+				}
+				break
+			}
+		}
+	}
+
+	var lastErr error = fmt.Errorf("no cluster network entries configured")
+	for _, e := range sa.entries {
+		sn, err := e.allocator.GetNetwork()
+		if err == nil {
+			return sn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (sa *subnetAllocator) ReleaseNetwork(sn *net.IPNet) error {
+	for _, e := range sa.entries {
+		if e.network.Contains(sn.IP) {
+			return e.allocator.ReleaseNetwork(sn)
+		}
+	}
+	return fmt.Errorf("host subnet %s is not part of any configured cluster network", sn.String())
+}
+
+func (master *OsdnMaster) SubnetStartMaster(clusterNetworks []sdnapi.ClusterNetworkEntry) error {
+	subnets, err := master.osClient.HostSubnets().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error in initializing/fetching subnets: %v", err)
+	}
+	existingSubnets := make([]*net.IPNet, 0, len(subnets.Items))
+	for _, sub := range subnets.Items {
+		_, sn, err := net.ParseCIDR(sub.Subnet)
+		if err != nil {
+			return fmt.Errorf("failed to parse existing host subnet %q: %v", sub.Subnet, err)
+		}
+		existingSubnets = append(existingSubnets, sn)
+	}
+
+	sa, err := newSubnetAllocator(clusterNetworks, existingSubnets)
+	if err != nil {
+		return err
+	}
+
+	master.networkMu.Lock()
+	defer master.networkMu.Unlock()
+	master.subnetAllocator = sa
+	return nil
+}
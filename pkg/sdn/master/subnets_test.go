@@ -0,0 +1,134 @@
+package master
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+// fakeAllocator is a networkAllocator that hands out a fixed response,
+// standing in for netutils.SubnetAllocator in tests.
+type fakeAllocator struct {
+	network  *net.IPNet
+	full     bool
+	got      bool
+	released *net.IPNet
+}
+
+func (f *fakeAllocator) GetNetwork() (*net.IPNet, error) {
+	if f.full {
+		return nil, fmt.Errorf("no addresses left")
+	}
+	f.got = true
+	return f.network, nil
+}
+
+func (f *fakeAllocator) ReleaseNetwork(sn *net.IPNet) error {
+	f.released = sn
+	return nil
+}
+
+func entry(t *testing.T, cidr string, a *fakeAllocator) *allocatorEntry {
+	t.Helper()
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", cidr, err)
+	}
+	a.network = network
+	return &allocatorEntry{network: network, allocator: a}
+}
+
+func TestSubnetAllocatorGetNetworkPrefersHint(t *testing.T) {
+	a1 := &fakeAllocator{}
+	a2 := &fakeAllocator{}
+	sa := &subnetAllocator{entries: []*allocatorEntry{
+		entry(t, "10.1.0.0/16", a1),
+		entry(t, "10.2.0.0/16", a2),
+	}}
+
+	sn, err := sa.GetNetwork(net.ParseIP("10.2.5.5"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a1.got {
+		t.Errorf("expected the non-matching entry not to be consulted")
+	}
+	if !a2.got || !sn.IP.Equal(a2.network.IP) {
+		t.Errorf("expected the hinted entry to be used")
+	}
+}
+
+func TestSubnetAllocatorGetNetworkFallsBackWhenHintedEntryIsFull(t *testing.T) {
+	a1 := &fakeAllocator{full: true}
+	a2 := &fakeAllocator{}
+	sa := &subnetAllocator{entries: []*allocatorEntry{
+		entry(t, "10.1.0.0/16", a1),
+		entry(t, "10.2.0.0/16", a2),
+	}}
+
+	sn, err := sa.GetNetwork(net.ParseIP("10.1.5.5"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !a2.got || !sn.IP.Equal(a2.network.IP) {
+		t.Errorf("expected allocation to fall through to the next entry once the hinted one is full")
+	}
+}
+
+func TestSubnetAllocatorGetNetworkWithoutHintTriesEachEntryInOrder(t *testing.T) {
+	a1 := &fakeAllocator{full: true}
+	a2 := &fakeAllocator{}
+	sa := &subnetAllocator{entries: []*allocatorEntry{
+		entry(t, "10.1.0.0/16", a1),
+		entry(t, "10.2.0.0/16", a2),
+	}}
+
+	if _, err := sa.GetNetwork(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !a2.got {
+		t.Errorf("expected the second entry to be tried once the first returns an error")
+	}
+}
+
+func TestSubnetAllocatorGetNetworkAllEntriesFull(t *testing.T) {
+	sa := &subnetAllocator{entries: []*allocatorEntry{
+		entry(t, "10.1.0.0/16", &fakeAllocator{full: true}),
+		entry(t, "10.2.0.0/16", &fakeAllocator{full: true}),
+	}}
+
+	if _, err := sa.GetNetwork(nil); err == nil {
+		t.Errorf("expected an error when every entry is full")
+	}
+}
+
+func TestSubnetAllocatorReleaseNetwork(t *testing.T) {
+	a1 := &fakeAllocator{}
+	a2 := &fakeAllocator{}
+	sa := &subnetAllocator{entries: []*allocatorEntry{
+		entry(t, "10.1.0.0/16", a1),
+		entry(t, "10.2.0.0/16", a2),
+	}}
+
+	_, sn, _ := net.ParseCIDR("10.2.3.0/24")
+	if err := sa.ReleaseNetwork(sn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a1.released != nil {
+		t.Errorf("expected the non-owning entry's allocator not to be released into")
+	}
+	if a2.released != sn {
+		t.Errorf("expected the owning entry's allocator to receive the release")
+	}
+}
+
+func TestSubnetAllocatorReleaseNetworkNotInAnyEntry(t *testing.T) {
+	sa := &subnetAllocator{entries: []*allocatorEntry{
+		entry(t, "10.1.0.0/16", &fakeAllocator{}),
+	}}
+
+	_, sn, _ := net.ParseCIDR("192.168.0.0/24")
+	if err := sa.ReleaseNetwork(sn); err == nil {
+		t.Errorf("expected an error releasing a subnet that belongs to no configured entry")
+	}
+}
@@ -0,0 +1,6 @@
+// Package node is the package for the node side of the SDN plugin (OVS flow
+// management, the CNI server, and kube-proxy integration), kept apart from
+// sdn/master so that master-only binaries don't need to link against
+// OVS/CNI dependencies. It declares no code: the node-side code is not in
+// this package.
+package node